@@ -0,0 +1,57 @@
+// Analysis of video file names to extract the movie/show title, the
+// season/episode (for series) and the release group.
+//
+// Copyright © 2014 - Rémy MATHIEU
+
+package analyzer
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Result of the analysis of a filename.
+type AnalysisResult struct {
+	Name         string // Guessed movie/show name.
+	IsSerie      bool   // Whether a season/episode couple was found.
+	Season       int
+	Episode      int
+	ReleaseGroup string // Guessed release group, e.g. "YIFY", empty if unknown.
+}
+
+// sNNeNN or NNxNN season/episode markers.
+var seasonEpisodeRegexp = regexp.MustCompile(`(?i)s(\d{1,2})e(\d{1,2})|(\d{1,2})x(\d{2})`)
+
+// Trailing "-GROUP" release group marker.
+var releaseGroupRegexp = regexp.MustCompile(`-([A-Za-z0-9]+)$`)
+
+// AnalyzeFilename guesses the movie/show name, season/episode and release
+// group out of a video file name or path.
+func AnalyzeFilename(filename string) AnalysisResult {
+	base := filepath.Base(filename)
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+
+	result := AnalysisResult{Name: base}
+
+	if match := seasonEpisodeRegexp.FindStringSubmatch(base); match != nil {
+		result.IsSerie = true
+		if match[1] != "" {
+			result.Season, _ = strconv.Atoi(match[1])
+			result.Episode, _ = strconv.Atoi(match[2])
+		} else {
+			result.Season, _ = strconv.Atoi(match[3])
+			result.Episode, _ = strconv.Atoi(match[4])
+		}
+		result.Name = strings.TrimSpace(base[:strings.Index(base, match[0])])
+	}
+
+	if match := releaseGroupRegexp.FindStringSubmatch(base); match != nil {
+		result.ReleaseGroup = match[1]
+	}
+
+	result.Name = strings.ReplaceAll(result.Name, ".", " ")
+
+	return result
+}
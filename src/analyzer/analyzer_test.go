@@ -0,0 +1,42 @@
+package analyzer
+
+import "testing"
+
+func TestAnalyzeFilenameMovie(t *testing.T) {
+	result := AnalyzeFilename("Some.Movie.2019.1080p-GROUP.mkv")
+
+	if result.IsSerie {
+		t.Error("expected a movie, got IsSerie = true")
+	}
+	if result.ReleaseGroup != "GROUP" {
+		t.Errorf("expected release group %q, got %q", "GROUP", result.ReleaseGroup)
+	}
+}
+
+func TestAnalyzeFilenameSerieSxxExx(t *testing.T) {
+	result := AnalyzeFilename("Some.Show.S02E05.720p-GROUP.mkv")
+
+	if !result.IsSerie {
+		t.Fatal("expected a serie, got IsSerie = false")
+	}
+	if result.Season != 2 {
+		t.Errorf("expected season 2, got %d", result.Season)
+	}
+	if result.Episode != 5 {
+		t.Errorf("expected episode 5, got %d", result.Episode)
+	}
+}
+
+func TestAnalyzeFilenameSerieNNxNN(t *testing.T) {
+	result := AnalyzeFilename("Some.Show.3x10.mkv")
+
+	if !result.IsSerie {
+		t.Fatal("expected a serie, got IsSerie = false")
+	}
+	if result.Season != 3 {
+		t.Errorf("expected season 3, got %d", result.Season)
+	}
+	if result.Episode != 10 {
+		t.Errorf("expected episode 10, got %d", result.Episode)
+	}
+}
@@ -0,0 +1,167 @@
+// Client for the OMDb API, used to enrich subtitle search results with
+// canonical movie/series metadata.
+//
+// Copyright © 2014 - Rémy MATHIEU
+
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+
+	"opensubtitles/model"
+)
+
+const omdbAPIURL = "http://www.omdbapi.com/"
+
+// A connected client to the OMDb API.
+type MetadataClient struct {
+	APIKey     string
+	HTTPClient *http.Client
+}
+
+// NewMetadataClient returns a MetadataClient using http.DefaultClient.
+func NewMetadataClient(apiKey string) MetadataClient {
+	return MetadataClient{
+		APIKey:     apiKey,
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// omdbMovie is the JSON shape of a single OMDb title response.
+type omdbMovie struct {
+	Title      string `json:"Title"`
+	Year       string `json:"Year"`
+	Runtime    string `json:"Runtime"`
+	Genre      string `json:"Genre"`
+	Plot       string `json:"Plot"`
+	Poster     string `json:"Poster"`
+	ImdbRating string `json:"imdbRating"`
+	ImdbID     string `json:"imdbID"`
+	Type       string `json:"Type"`
+	Season     string `json:"Season,omitempty"`
+	Episode    string `json:"Episode,omitempty"`
+	Response   string `json:"Response"`
+	Error      string `json:"Error"`
+}
+
+// searchResult is the shape of OMDb's "s=" title search response.
+type searchResult struct {
+	Search   []omdbMovie `json:"Search"`
+	Response string      `json:"Response"`
+	Error    string      `json:"Error"`
+}
+
+func (m omdbMovie) toModel() *model.Movie {
+	return &model.Movie{
+		Title:      m.Title,
+		Year:       m.Year,
+		Runtime:    m.Runtime,
+		Genre:      m.Genre,
+		Plot:       m.Plot,
+		Poster:     m.Poster,
+		ImdbRating: m.ImdbRating,
+		ImdbID:     m.ImdbID,
+		Type:       m.Type,
+		Season:     m.Season,
+		Episode:    m.Episode,
+	}
+}
+
+// GetByIMDbID fetches the metadata for the movie/series identified by id,
+// which must already be in the "ttXXXXXXX" format (see OSClient's
+// reformatIMDBId).
+func (c MetadataClient) GetByIMDbID(id string) (*model.Movie, error) {
+	values := url.Values{}
+	values.Set("i", id)
+	values.Set("apikey", c.APIKey)
+
+	var movie omdbMovie
+	if err := c.get(values, &movie); err != nil {
+		return nil, err
+	}
+
+	if movie.Response == "False" {
+		return nil, fmt.Errorf("metadata: OMDb returned an error for %s : %s\n", id, movie.Error)
+	}
+
+	return movie.toModel(), nil
+}
+
+// SearchByTitle searches OMDb by title. searchType, if non-empty, restricts
+// the search to "movie", "series" or "episode".
+func (c MetadataClient) SearchByTitle(query string, searchType string) ([]model.Movie, error) {
+	values := url.Values{}
+	values.Set("s", query)
+	values.Set("apikey", c.APIKey)
+	if searchType != "" {
+		values.Set("type", searchType)
+	}
+
+	var result searchResult
+	if err := c.get(values, &result); err != nil {
+		return nil, err
+	}
+
+	if result.Response == "False" {
+		return nil, fmt.Errorf("metadata: OMDb returned an error for %q : %s\n", query, result.Error)
+	}
+
+	movies := make([]model.Movie, len(result.Search))
+	for i, m := range result.Search {
+		movies[i] = *m.toModel()
+	}
+
+	return movies, nil
+}
+
+// EnrichSearchResponse decorates each entry of resp with the movie metadata
+// matching its IMDb ID, under entry.Movie. OMDb not knowing about a given ID
+// is an expected occurrence, not a reason to fail the whole batch ; such
+// entries are logged and left with a nil Movie.
+func (c MetadataClient) EnrichSearchResponse(resp *model.SearchSubtitlesResponse) error {
+	cache := make(map[string]*model.Movie)
+
+	for i := range resp.SubtitleEntries {
+		entry := &resp.SubtitleEntries[i]
+
+		if entry.IDMovieImdb == "" {
+			continue
+		}
+
+		movie, found := cache[entry.IDMovieImdb]
+		if !found {
+			var err error
+			movie, err = c.GetByIMDbID(entry.IDMovieImdb)
+			if err != nil {
+				log.Printf("metadata: couldn't enrich %s : %s", entry.IDMovieImdb, err)
+				cache[entry.IDMovieImdb] = nil
+				continue
+			}
+			cache[entry.IDMovieImdb] = movie
+		}
+
+		entry.Movie = movie
+	}
+
+	return nil
+}
+
+// get issues a GET request against the OMDb API and decodes the JSON
+// response into out.
+func (c MetadataClient) get(values url.Values, out interface{}) error {
+	resp, err := c.HTTPClient.Get(omdbAPIURL + "?" + values.Encode())
+	if err != nil {
+		return fmt.Errorf("metadata: error while calling the OMDb API : %s\n", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("metadata: bad HTTP status returned by the OMDb API : %s\n", resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
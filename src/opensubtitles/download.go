@@ -0,0 +1,108 @@
+// Downloading of subtitle files from OpenSubtitles.
+//
+// Copyright © 2014 - Rémy MATHIEU
+
+package opensubtitles
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"opensubtitles/model"
+
+	"golang.org/x/net/html/charset"
+)
+
+// Download fetches the subtitle files identified by subtitleFileIDs (the
+// IDSubtitleFile field of a SubtitleEntry) through the OpenSubtitles
+// DownloadSubtitles call. Each subtitle is returned gzip-compressed and
+// base64-encoded by the API ; it is decoded, gunzipped and transcoded to
+// UTF-8 before being handed back.
+func (c *OSClient) Download(subtitleFileIDs []string) ([]model.SubtitleFile, error) {
+	token, _, _ := c.session()
+	resp, err := c.httpCall("DownloadSubtitles", token, subtitleFileIDs)
+
+	if err != nil {
+		return nil, fmt.Errorf("Error code while logging to the OpenSubtitles API : %s\n", err)
+	}
+
+	var downloadResponse model.DownloadSubtitlesResponse
+	err = resp.Unmarshal(&downloadResponse)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if downloadResponse.Status != "200 OK" {
+		return nil, fmt.Errorf("Bad status code returned during download query :%s\n", downloadResponse.Status)
+	}
+
+	for i := range downloadResponse.SubtitleFiles {
+		raw, err := decodeSubtitleData(downloadResponse.SubtitleFiles[i].Data)
+		if err != nil {
+			return nil, err
+		}
+		downloadResponse.SubtitleFiles[i].Data = string(raw)
+	}
+
+	return downloadResponse.SubtitleFiles, nil
+}
+
+// DownloadTo downloads the subtitle described by entry and writes it to
+// destPath. If destPath is empty, it is derived from videoPath instead, i.e.
+// <basename-of-videoPath>.srt, written alongside the video file ; videoPath
+// itself may be left empty too, in which case the subtitle is written to
+// entry.SubFileName in the current working directory.
+func (c *OSClient) DownloadTo(entry model.SubtitleEntry, videoPath string, destPath string) error {
+	files, err := c.Download([]string{entry.IDSubtitleFile})
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		return fmt.Errorf("opensubtitles: no subtitle file returned for %s\n", entry.IDSubtitleFile)
+	}
+
+	if destPath == "" {
+		if videoPath != "" {
+			destPath = strings.TrimSuffix(videoPath, filepath.Ext(videoPath)) + ".srt"
+		} else {
+			destPath = entry.SubFileName
+		}
+	}
+
+	return ioutil.WriteFile(destPath, []byte(files[0].Data), 0644)
+}
+
+// decodeSubtitleData base64-decodes and gunzips the raw data returned by
+// DownloadSubtitles, then transcodes it to UTF-8 using the detected source
+// encoding.
+func decodeSubtitleData(data string) ([]byte, error) {
+	compressed, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("opensubtitles: couldn't base64-decode subtitle data : %s\n", err)
+	}
+
+	gzipReader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("opensubtitles: couldn't gunzip subtitle data : %s\n", err)
+	}
+	defer gzipReader.Close()
+
+	raw, err := ioutil.ReadAll(gzipReader)
+	if err != nil {
+		return nil, fmt.Errorf("opensubtitles: couldn't read gunzipped subtitle data : %s\n", err)
+	}
+
+	utf8Reader, err := charset.NewReader(bytes.NewReader(raw), "")
+	if err != nil {
+		return nil, fmt.Errorf("opensubtitles: couldn't detect subtitle encoding : %s\n", err)
+	}
+
+	return ioutil.ReadAll(utf8Reader)
+}
@@ -0,0 +1,38 @@
+package opensubtitles
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"testing"
+)
+
+func TestDecodeSubtitleDataRoundTrip(t *testing.T) {
+	want := "1\n00:00:01,000 --> 00:00:02,000\nHello, world!\n"
+
+	var gzipped bytes.Buffer
+	gzipWriter := gzip.NewWriter(&gzipped)
+	if _, err := gzipWriter.Write([]byte(want)); err != nil {
+		t.Fatalf("couldn't gzip test data : %s", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatalf("couldn't close gzip writer : %s", err)
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(gzipped.Bytes())
+
+	got, err := decodeSubtitleData(encoded)
+	if err != nil {
+		t.Fatalf("decodeSubtitleData returned an error : %s", err)
+	}
+
+	if string(got) != want {
+		t.Errorf("expected %q, got %q", want, string(got))
+	}
+}
+
+func TestDecodeSubtitleDataBadBase64(t *testing.T) {
+	if _, err := decodeSubtitleData("not valid base64!!!"); err == nil {
+		t.Error("expected an error for invalid base64 input, got none")
+	}
+}
@@ -0,0 +1,81 @@
+// Ranking and selection of the best subtitle out of a search response.
+//
+// Copyright © 2014 - Rémy MATHIEU
+
+package model
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"analyzer"
+)
+
+// Best returns the top ranked subtitle entry, as ordered by BestN, or nil if
+// the response holds no entry.
+func (r SearchSubtitlesResponse) Best() *SubtitleEntry {
+	best := r.BestN(1)
+	if len(best) == 0 {
+		return nil
+	}
+	return &best[0]
+}
+
+// BestN returns up to n subtitle entries, ranked from best to worst match.
+//
+// Entries matched by moviehash are always preferred, since they are tied to
+// the exact release rather than a fuzzy title guess. Download count, user
+// rating, whether a release group could be identified and the subtitle
+// format (srt preferred over sub/ass) break remaining ties.
+func (r SearchSubtitlesResponse) BestN(n int) []SubtitleEntry {
+	entries := make([]SubtitleEntry, len(r.SubtitleEntries))
+	copy(entries, r.SubtitleEntries)
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		iHash := entries[i].MatchedBy == "moviehash"
+		jHash := entries[j].MatchedBy == "moviehash"
+		if iHash != jHash {
+			return iHash
+		}
+		return score(entries[i]) > score(entries[j])
+	})
+
+	if n < 0 {
+		n = 0
+	}
+	if n > len(entries) {
+		n = len(entries)
+	}
+
+	return entries[:n]
+}
+
+// score ranks a subtitle entry on download count, rating, release group
+// identification and format ; it does not take MatchedBy into account, since
+// that's handled separately as a higher-priority sort key in BestN. Higher is
+// better.
+func score(entry SubtitleEntry) float64 {
+	var s float64
+
+	if downloads, err := strconv.Atoi(entry.SubDownloadsCnt); err == nil {
+		s += float64(downloads) * 0.01
+	}
+
+	if rating, err := strconv.ParseFloat(entry.SubRating, 64); err == nil {
+		s += rating * 10
+	}
+
+	if analyzer.AnalyzeFilename(entry.MovieReleaseName).ReleaseGroup != "" {
+		s += 5
+	}
+
+	switch strings.ToLower(entry.SubFormat) {
+	case "srt":
+		s += 20
+	case "sub", "ass":
+		s += 5
+	}
+
+	return s
+}
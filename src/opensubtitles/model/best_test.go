@@ -0,0 +1,64 @@
+package model
+
+import "testing"
+
+func TestBestPrefersMoviehashMatch(t *testing.T) {
+	resp := SearchSubtitlesResponse{
+		SubtitleEntries: []SubtitleEntry{
+			{MatchedBy: "tag", SubFormat: "srt", SubDownloadsCnt: "100000", SubRating: "9.0"},
+			{MatchedBy: "moviehash", SubFormat: "sub", SubDownloadsCnt: "0", SubRating: "0"},
+		},
+	}
+
+	best := resp.Best()
+	if best == nil {
+		t.Fatal("expected a best entry, got nil")
+	}
+	if best.MatchedBy != "moviehash" {
+		t.Errorf("expected the moviehash-matched entry to win regardless of downloads/rating, got MatchedBy = %q", best.MatchedBy)
+	}
+}
+
+func TestBestPrefersSrtFormat(t *testing.T) {
+	resp := SearchSubtitlesResponse{
+		SubtitleEntries: []SubtitleEntry{
+			{SubFormat: "sub"},
+			{SubFormat: "srt"},
+		},
+	}
+
+	best := resp.Best()
+	if best == nil || best.SubFormat != "srt" {
+		t.Errorf("expected the srt entry to win on otherwise equal entries, got %+v", best)
+	}
+}
+
+func TestBestNReturnsAtMostN(t *testing.T) {
+	resp := SearchSubtitlesResponse{
+		SubtitleEntries: []SubtitleEntry{{}, {}, {}},
+	}
+
+	if got := len(resp.BestN(2)); got != 2 {
+		t.Errorf("expected 2 entries, got %d", got)
+	}
+	if got := len(resp.BestN(10)); got != 3 {
+		t.Errorf("expected 3 entries when n exceeds the result count, got %d", got)
+	}
+}
+
+func TestBestNWithNegativeN(t *testing.T) {
+	resp := SearchSubtitlesResponse{
+		SubtitleEntries: []SubtitleEntry{{}, {}},
+	}
+
+	if got := len(resp.BestN(-1)); got != 0 {
+		t.Errorf("expected 0 entries for a negative n, got %d", got)
+	}
+}
+
+func TestBestOfEmptyResponse(t *testing.T) {
+	resp := SearchSubtitlesResponse{}
+	if best := resp.Best(); best != nil {
+		t.Errorf("expected nil for an empty response, got %+v", best)
+	}
+}
@@ -0,0 +1,53 @@
+// Data structures returned by the OpenSubtitles XML-RPC API.
+//
+// Copyright © 2014 - Rémy MATHIEU
+
+package model
+
+// Response to the LogIn call.
+type LogInResponse struct {
+	Status string `xmlrpc:"status"`
+	Token  string `xmlrpc:"token"`
+}
+
+// Response to the LogOut call.
+type LogOutResponse struct {
+	Status string `xmlrpc:"status"`
+}
+
+// Response to the SearchSubtitles call.
+type SearchSubtitlesResponse struct {
+	Status          string          `xmlrpc:"status"`
+	SubtitleEntries []SubtitleEntry `xmlrpc:"data"`
+}
+
+// A single subtitle as returned by SearchSubtitles.
+type SubtitleEntry struct {
+	IDSubtitleFile   string `xmlrpc:"IDSubtitleFile"`
+	SubFileName      string `xmlrpc:"SubFileName"`
+	SubFormat        string `xmlrpc:"SubFormat"`
+	SubLanguageID    string `xmlrpc:"SubLanguageID"`
+	SubDownloadsCnt  string `xmlrpc:"SubDownloadsCnt"`
+	SubRating        string `xmlrpc:"SubRating"`
+	MatchedBy        string `xmlrpc:"MatchedBy"`
+	MovieReleaseName string `xmlrpc:"MovieReleaseName"`
+	MovieName        string `xmlrpc:"MovieName"`
+	IDMovieImdb      string `xmlrpc:"IDMovieImdb"`
+
+	// Movie is filled in by a metadata provider, e.g. via
+	// MetadataClient.EnrichSearchResponse ; it is nil until then.
+	Movie *Movie
+}
+
+// Response to the DownloadSubtitles call.
+type DownloadSubtitlesResponse struct {
+	Status        string         `xmlrpc:"status"`
+	SubtitleFiles []SubtitleFile `xmlrpc:"data"`
+}
+
+// A single downloaded subtitle file, base64-encoded and gzip-compressed by
+// the API.
+type SubtitleFile struct {
+	IDSubtitleFile string `xmlrpc:"idsubtitlefile"`
+	Data           string `xmlrpc:"data"`
+}
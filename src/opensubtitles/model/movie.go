@@ -0,0 +1,21 @@
+// Canonical movie/series metadata, as fetched from a metadata provider
+// such as OMDb and attached to a SubtitleEntry.
+//
+// Copyright © 2014 - Rémy MATHIEU
+
+package model
+
+// Movie holds the canonical metadata for a movie, series or episode.
+type Movie struct {
+	Title      string
+	Year       string
+	Runtime    string
+	Genre      string
+	Plot       string
+	Poster     string
+	ImdbRating string
+	ImdbID     string
+	Type       string
+	Season     string
+	Episode    string
+}
@@ -0,0 +1,69 @@
+// Implementation of the OpenSubtitles "moviehash" algorithm.
+//
+// Copyright © 2014 - Rémy MATHIEU
+
+package opensubtitles
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+)
+
+// Size, in bytes, of the head and tail chunks read from the file.
+const hashChunkSize = 65536
+
+// Minimum file size for which the moviehash algorithm is defined : two
+// non-overlapping 64 KiB chunks.
+const minHashableSize = hashChunkSize * 2
+
+// MovieHash computes the OpenSubtitles moviehash fingerprint of the file at
+// path : the filesize plus the sum, as consecutive little-endian uint64
+// values, of its first and last 64 KiB, all wrapped modulo 2^64. It returns
+// the hash formatted as a 16 char lowercase hex string along with the file
+// size in bytes.
+//
+// Files smaller than 128 KiB have no defined hash and return an error.
+func MovieHash(path string) (string, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", 0, err
+	}
+
+	size := info.Size()
+	if size < minHashableSize {
+		return "", 0, fmt.Errorf("opensubtitles: file %s is too small (%d bytes) to compute a moviehash, need at least %d bytes\n", path, size, minHashableSize)
+	}
+
+	hash := uint64(size)
+
+	if err := sumChunk(file, 0, &hash); err != nil {
+		return "", 0, err
+	}
+	if err := sumChunk(file, size-hashChunkSize, &hash); err != nil {
+		return "", 0, err
+	}
+
+	return fmt.Sprintf("%016x", hash), size, nil
+}
+
+// sumChunk reads a hashChunkSize chunk at offset and adds its contents,
+// read as consecutive little-endian uint64 values, to hash.
+func sumChunk(file *os.File, offset int64, hash *uint64) error {
+	buf := make([]byte, hashChunkSize)
+	if _, err := file.ReadAt(buf, offset); err != nil {
+		return err
+	}
+
+	for i := 0; i < len(buf); i += 8 {
+		*hash += binary.LittleEndian.Uint64(buf[i : i+8])
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package opensubtitles
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func writeTempFile(t *testing.T, content []byte) string {
+	f, err := ioutil.TempFile("", "moviehash")
+	if err != nil {
+		t.Fatalf("couldn't create temp file : %s", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(content); err != nil {
+		t.Fatalf("couldn't write temp file : %s", err)
+	}
+
+	return f.Name()
+}
+
+func TestMovieHashOfZeroedFile(t *testing.T) {
+	// An all-zero file contributes nothing from either chunk, so the hash
+	// is just the filesize itself, formatted as 16 char lowercase hex.
+	size := int64(minHashableSize)
+	path := writeTempFile(t, make([]byte, size))
+	defer os.Remove(path)
+
+	hash, gotSize, err := MovieHash(path)
+	if err != nil {
+		t.Fatalf("MovieHash returned an error : %s", err)
+	}
+
+	if gotSize != size {
+		t.Errorf("expected size %d, got %d", size, gotSize)
+	}
+
+	want := fmt.Sprintf("%016x", size)
+	if hash != want {
+		t.Errorf("expected hash %q, got %q", want, hash)
+	}
+}
+
+func TestMovieHashTooSmall(t *testing.T) {
+	path := writeTempFile(t, make([]byte, minHashableSize-1))
+	defer os.Remove(path)
+
+	if _, _, err := MovieHash(path); err == nil {
+		t.Error("expected an error for a file smaller than 128 KiB, got none")
+	}
+}
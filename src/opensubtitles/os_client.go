@@ -11,6 +11,9 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"analyzer"
 	"opensubtitles/model"
@@ -24,11 +27,48 @@ const (
 
 // A connected client to the OpenSubtitles platform.
 type OSClient struct {
-	Token     string // Identification token
 	UserAgent string // User agent used for identification to OpenSubtitles
 	Language  string // Language given during the opening of the connection
 
 	httpClient http.Client // HTTP Client
+
+	// sessionMu guards token, username and password : StartKeepAlive's
+	// goroutine and foreground calls can both trigger a re-login
+	// concurrently, and both read the token to issue their own calls. The
+	// token is unexported (see Token) so that this mutex is the only way to
+	// touch it.
+	sessionMu sync.Mutex
+	token     string
+
+	// Credentials cached from the last LogIn call, reused to transparently
+	// re-authenticate whenever the token expires.
+	username string
+	password string
+}
+
+// Token returns the client's current identification token, safe for
+// concurrent use even while StartKeepAlive is running.
+func (c *OSClient) Token() string {
+	token, _, _ := c.session()
+	return token
+}
+
+// session returns the current token and cached credentials, safe for
+// concurrent use.
+func (c *OSClient) session() (token, username, password string) {
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
+	return c.token, c.username, c.password
+}
+
+// setSession updates the token and cached credentials, safe for concurrent
+// use.
+func (c *OSClient) setSession(token, username, password string) {
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
+	c.token = token
+	c.username = username
+	c.password = password
 }
 
 func NewOSClient(language string, userAgent string) OSClient {
@@ -38,9 +78,10 @@ func NewOSClient(language string, userAgent string) OSClient {
 	}
 }
 
-// Log in to the OpenSubtitles platform.
+// Log in to the OpenSubtitles platform. OpenSubtitles allows anonymous
+// access, so username and password may both be left empty.
 func (c *OSClient) LogIn(username string, password string) error {
-	resp, err := c.httpCall("LogIn", username, password, c.Language, c.UserAgent)
+	resp, err := c.rawCall("LogIn", username, password, c.Language, c.UserAgent)
 
 	if err != nil {
 		return fmt.Errorf("Error code while logging to the OpenSubtitles API : %s\n", err)
@@ -49,14 +90,15 @@ func (c *OSClient) LogIn(username string, password string) error {
 	var loginResponse model.LogInResponse
 	resp.Unmarshal(&loginResponse)
 
-	c.Token = loginResponse.Token
+	c.setSession(loginResponse.Token, username, password)
 
 	return nil
 }
 
 // Log out an user. Returns whether or not a 200 has been returned.
 func (c *OSClient) LogOut() error {
-	resp, err := c.httpCall("LogOut", c.Token)
+	token, _, _ := c.session()
+	resp, err := c.httpCall("LogOut", token)
 
 	if err != nil {
 		return fmt.Errorf("Error code while logging to the OpenSubtitles API : %s\n", err)
@@ -95,7 +137,8 @@ func (c *OSClient) Search(filename string, language string, limit int) (model.Se
 	options := make(map[string]int)
 	options["limit"] = limit
 
-	resp, err := c.httpCall("SearchSubtitles", c.Token, filters, options)
+	token, _, _ := c.session()
+	resp, err := c.httpCall("SearchSubtitles", token, filters, options)
 
 	if err != nil {
 		return emptyResponse, fmt.Errorf("Error code while logging to the OpenSubtitles API : %s\n", err)
@@ -122,8 +165,110 @@ func (c *OSClient) Search(filename string, language string, limit int) (model.Se
 	return searchResponse, nil
 }
 
-// Does the XML-RPC over HTTP call.
+// Looks for a subtitle given the movie file itself, using the OpenSubtitles
+// moviehash fingerprint. This matches the exact release rather than doing a
+// fuzzy title/season/episode guess, so the query-based filter built from
+// filename is kept as a fallback in the same request.
+func (c *OSClient) SearchByHash(path string, language string, limit int) (model.SearchSubtitlesResponse, error) {
+	emptyResponse := model.SearchSubtitlesResponse{}
+
+	hash, size, err := MovieHash(path)
+	if err != nil {
+		return emptyResponse, err
+	}
+
+	// Builds the hash-based filter.
+	filter := make(map[string]string)
+	filter["moviehash"] = hash
+	filter["moviebytesize"] = strconv.FormatInt(size, 10)
+	filter["sublanguageid"] = language
+
+	filters := []map[string]string{filter}
+
+	// Fallback : also search by filename, in case the hash isn't matched.
+	result := analyzer.AnalyzeFilename(path)
+	fallback := make(map[string]string)
+	fallback["query"] = result.Name
+	fallback["sublanguageid"] = language
+	if result.IsSerie {
+		fallback["season"] = strconv.Itoa(result.Season)
+		fallback["episode"] = strconv.Itoa(result.Episode)
+	}
+	filters = append(filters, fallback)
+
+	// Query options, currently, we just put a limit.
+	options := make(map[string]int)
+	options["limit"] = limit
+
+	token, _, _ := c.session()
+	resp, err := c.httpCall("SearchSubtitles", token, filters, options)
+
+	if err != nil {
+		return emptyResponse, fmt.Errorf("Error code while logging to the OpenSubtitles API : %s\n", err)
+	}
+
+	// Reads the response
+	var searchResponse model.SearchSubtitlesResponse
+	err = resp.Unmarshal(&searchResponse)
+
+	if err != nil {
+		return emptyResponse, err
+	}
+
+	if searchResponse.Status != "200 OK" {
+		return emptyResponse, fmt.Errorf("Bad status code returned during search query :%s\n", searchResponse.Status)
+	}
+
+	// Fill with 0 for the imdb ID.
+	for i := 0; i < len(searchResponse.SubtitleEntries); i++ {
+		entry := &searchResponse.SubtitleEntries[i]
+		entry.IDMovieImdb = reformatIMDBId((*entry).IDMovieImdb)
+	}
+
+	return searchResponse, nil
+}
+
+// Does the XML-RPC over HTTP call, transparently re-authenticating and
+// retrying once when the token has expired.
 func (c *OSClient) httpCall(method string, parameters ...interface{}) (*xmlrpc.Response, error) {
+	resp, err := c.rawCall(method, parameters...)
+	if err != nil {
+		return nil, err
+	}
+
+	if method == "LogIn" || !sessionExpired(resp) {
+		return resp, nil
+	}
+
+	_, username, password := c.session()
+	if err := c.LogIn(username, password); err != nil {
+		return nil, fmt.Errorf("Error code while re-authenticating to the OpenSubtitles API : %s\n", err)
+	}
+
+	// The token parameter, when there's one, always comes first.
+	if len(parameters) > 0 {
+		if _, ok := parameters[0].(string); ok {
+			token, _, _ := c.session()
+			parameters[0] = token
+		}
+	}
+
+	return c.rawCall(method, parameters...)
+}
+
+// sessionExpired reports whether resp carries a status indicating the
+// session token is no longer valid.
+func sessionExpired(resp *xmlrpc.Response) bool {
+	var status struct {
+		Status string `xmlrpc:"status"`
+	}
+	resp.Unmarshal(&status)
+
+	return strings.Contains(status.Status, "406") || strings.Contains(status.Status, "401")
+}
+
+// Does the XML-RPC over HTTP call.
+func (c *OSClient) rawCall(method string, parameters ...interface{}) (*xmlrpc.Response, error) {
 	// Will do many tries in case of 503
 	triesLeft := 3
 
@@ -162,6 +307,33 @@ func (c *OSClient) httpCall(method string, parameters ...interface{}) (*xmlrpc.R
 	return xmlrpc.NewResponse(data), nil
 }
 
+// StartKeepAlive starts a goroutine sending a NoOperation call every
+// interval, to stop OpenSubtitles from expiring the session token after
+// ~15 minutes of idleness. Sending on (or closing) the returned channel
+// stops it.
+func (c *OSClient) StartKeepAlive(interval time.Duration) chan<- struct{} {
+	stop := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				token, _, _ := c.session()
+				if _, err := c.httpCall("NoOperation", token); err != nil {
+					log.Println("OpenSubtitles keepalive failed:", err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return stop
+}
+
 // An ID of IMDB is :
 // ttXXXXXXX
 // But from OS we receive XXXX where the number
@@ -0,0 +1,82 @@
+// Adapts OSClient to the source.Source interface, so OpenSubtitles can be
+// registered alongside other subtitle sources in a source.Aggregator.
+//
+// Copyright © 2014 - Rémy MATHIEU
+
+package opensubtitles
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"opensubtitles/model"
+
+	"source"
+)
+
+// SourceAdapter wraps an OSClient so it satisfies source.Source.
+type SourceAdapter struct {
+	Client *OSClient
+}
+
+// NewSourceAdapter returns a source.Source backed by client.
+func NewSourceAdapter(client *OSClient) SourceAdapter {
+	return SourceAdapter{Client: client}
+}
+
+func (a SourceAdapter) Name() string {
+	return "opensubtitles"
+}
+
+// Search looks the query up by moviehash when a file path is given,
+// falling back to the filename-based query otherwise.
+func (a SourceAdapter) Search(q source.Query) ([]source.Subtitle, error) {
+	var resp model.SearchSubtitlesResponse
+	var err error
+
+	if q.Path != "" {
+		resp, err = a.Client.SearchByHash(q.Path, q.Language, q.Limit)
+	} else {
+		resp, err = a.Client.Search(q.Filename, q.Language, q.Limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	subs := make([]source.Subtitle, len(resp.SubtitleEntries))
+	for i, entry := range resp.SubtitleEntries {
+		rating, _ := strconv.ParseFloat(entry.SubRating, 64)
+
+		subs[i] = source.Subtitle{
+			Title:      entry.MovieReleaseName,
+			Language:   entry.SubLanguageID,
+			Format:     entry.SubFormat,
+			Rating:     rating,
+			SourceName: a.Name(),
+			Ref:        entry,
+		}
+	}
+
+	return subs, nil
+}
+
+// Download fetches the subtitle file backing sub.
+func (a SourceAdapter) Download(sub source.Subtitle) (io.ReadCloser, error) {
+	entry, ok := sub.Ref.(model.SubtitleEntry)
+	if !ok {
+		return nil, fmt.Errorf("opensubtitles: subtitle %q wasn't returned by this source\n", sub.Title)
+	}
+
+	files, err := a.Client.Download([]string{entry.IDSubtitleFile})
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("opensubtitles: no subtitle file returned for %s\n", entry.IDSubtitleFile)
+	}
+
+	return ioutil.NopCloser(strings.NewReader(files[0].Data)), nil
+}
@@ -0,0 +1,98 @@
+// Pluggable subtitle sources, and an aggregator able to fan a query out
+// across several of them at once.
+//
+// Copyright © 2014 - Rémy MATHIEU
+
+package source
+
+import (
+	"io"
+	"sync"
+)
+
+// A Query describes what to look a subtitle up for.
+type Query struct {
+	Filename string // Video filename, used for title/season/episode guessing.
+	Path     string // Path to the video file on disk, used for hash-based lookups. May be empty.
+	Language string // Wanted subtitle language.
+	Limit    int    // Max number of results wanted from a single source.
+}
+
+// A Subtitle is a single result returned by a Source.
+type Subtitle struct {
+	Title      string // Release/title this subtitle was matched against.
+	Language   string
+	Format     string // e.g. "srt", "sub".
+	Rating     float64
+	SourceName string // Name of the Source that returned this result.
+
+	// Opaque data the Source needs to later Download this subtitle.
+	Ref interface{}
+}
+
+// A Source knows how to search for and download subtitles.
+type Source interface {
+	// Name identifies the source, e.g. "opensubtitles" or "yifysubtitles".
+	Name() string
+	// Search looks up subtitles matching q.
+	Search(q Query) ([]Subtitle, error)
+	// Download returns a reader over the raw subtitle file content for sub.
+	Download(sub Subtitle) (io.ReadCloser, error)
+}
+
+// An Aggregator fans a Query out across all its registered Sources
+// concurrently and merges the results, so that callers get broader
+// coverage and keep working when a single source is unavailable.
+type Aggregator struct {
+	Sources []Source
+}
+
+// NewAggregator returns an Aggregator fanning queries out to sources.
+func NewAggregator(sources ...Source) *Aggregator {
+	return &Aggregator{Sources: sources}
+}
+
+// result holds a single source's response : its subtitles, or its error.
+type result struct {
+	subtitles []Subtitle
+	err       error
+}
+
+// Search queries every registered source concurrently and returns the
+// merged, deduplicated results. A source that errors out doesn't fail the
+// whole search ; it's simply skipped.
+func (a *Aggregator) Search(q Query) ([]Subtitle, error) {
+	results := make([]result, len(a.Sources))
+
+	var wg sync.WaitGroup
+	for i, src := range a.Sources {
+		wg.Add(1)
+		go func(i int, src Source) {
+			defer wg.Done()
+			subs, err := src.Search(q)
+			results[i] = result{subtitles: subs, err: err}
+		}(i, src)
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	merged := make([]Subtitle, 0)
+
+	for _, r := range results {
+		if r.err != nil {
+			continue
+		}
+		for _, sub := range r.subtitles {
+			// Deliberately omits SourceName : the point of aggregating is to
+			// recognize the same release when two sources both return it.
+			key := sub.Language + "|" + sub.Title
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, sub)
+		}
+	}
+
+	return merged, nil
+}
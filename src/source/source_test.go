@@ -0,0 +1,84 @@
+package source
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"testing"
+)
+
+// fakeSource is a Source whose Search results are fixed in advance, for
+// testing Aggregator without any real network access.
+type fakeSource struct {
+	name    string
+	results []Subtitle
+	err     error
+}
+
+func (s fakeSource) Name() string { return s.name }
+
+func (s fakeSource) Search(q Query) ([]Subtitle, error) {
+	return s.results, s.err
+}
+
+func (s fakeSource) Download(sub Subtitle) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func TestAggregatorSearchDedupesAcrossSources(t *testing.T) {
+	a := NewAggregator(
+		fakeSource{
+			name: "opensubtitles",
+			results: []Subtitle{
+				{Title: "Movie.2019.1080p-GROUP", Language: "eng", SourceName: "opensubtitles"},
+			},
+		},
+		fakeSource{
+			name: "yifysubtitles",
+			results: []Subtitle{
+				// Same release as above, returned by a different source.
+				{Title: "Movie.2019.1080p-GROUP", Language: "eng", SourceName: "yifysubtitles"},
+				{Title: "Other.Movie.2020.720p-GROUP", Language: "eng", SourceName: "yifysubtitles"},
+			},
+		},
+	)
+
+	subs, err := a.Search(Query{Filename: "Movie.2019.1080p-GROUP.mkv"})
+	if err != nil {
+		t.Fatalf("Search returned an error : %s", err)
+	}
+
+	if len(subs) != 2 {
+		t.Fatalf("expected 2 deduplicated results, got %d : %+v", len(subs), subs)
+	}
+
+	titles := make([]string, len(subs))
+	for i, s := range subs {
+		titles[i] = s.Title
+	}
+	sort.Strings(titles)
+
+	want := []string{"Movie.2019.1080p-GROUP", "Other.Movie.2020.720p-GROUP"}
+	for i := range want {
+		if titles[i] != want[i] {
+			t.Errorf("expected titles %v, got %v", want, titles)
+			break
+		}
+	}
+}
+
+func TestAggregatorSearchSkipsFailingSources(t *testing.T) {
+	a := NewAggregator(
+		fakeSource{name: "broken", err: fmt.Errorf("unavailable")},
+		fakeSource{name: "ok", results: []Subtitle{{Title: "Movie", Language: "eng"}}},
+	)
+
+	subs, err := a.Search(Query{})
+	if err != nil {
+		t.Fatalf("Search returned an error : %s", err)
+	}
+
+	if len(subs) != 1 {
+		t.Fatalf("expected the failing source to be skipped, got %d results", len(subs))
+	}
+}
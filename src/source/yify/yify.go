@@ -0,0 +1,156 @@
+// Scraper-based subtitle source backed by yifysubtitles.org.
+//
+// Copyright © 2014 - Rémy MATHIEU
+
+package yify
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"source"
+)
+
+const baseURL = "https://yifysubtitles.org"
+
+// Source scrapes yifysubtitles.org for subtitles.
+type Source struct {
+	HTTPClient *http.Client
+}
+
+// New returns a yify Source using http.DefaultClient.
+func New() Source {
+	return Source{HTTPClient: http.DefaultClient}
+}
+
+func (s Source) Name() string {
+	return "yifysubtitles"
+}
+
+// movieLinkRegexp matches the links to individual movie subtitle pages
+// (e.g. /movie-imdb/tt1234567) listed on a search results page.
+var movieLinkRegexp = regexp.MustCompile(`(?i)href="(/movie-imdb/tt\d+)"`)
+
+// rowRegexp matches a single <tr> of the table.other-subs rows on a movie's
+// subtitle page and captures its rating cell, language, zip link and title.
+// The uploader cell is skipped, not captured.
+var rowRegexp = regexp.MustCompile(`(?is)<tr>.*?rating.*?>(\d+)<.*?<span class="sub-lang">([^<]+)</span>.*?<a href="([^"]+)"[^>]*class="subtitle-download"[^>]*>.*?<span>([^<]*)</span>`)
+
+// Search scrapes the yifysubtitles search results for movies matching q,
+// then scrapes each matching movie's own subtitle page (the only place
+// table.other-subs rows actually live) for its subtitles.
+func (s Source) Search(q source.Query) ([]source.Subtitle, error) {
+	searchURL := fmt.Sprintf("%s/search?q=%s", baseURL, strings.ReplaceAll(q.Filename, " ", "+"))
+
+	body, err := s.get(searchURL)
+	if err != nil {
+		return nil, err
+	}
+
+	movieLinks := uniqueMatches(movieLinkRegexp, body)
+
+	subs := make([]source.Subtitle, 0)
+	for _, link := range movieLinks {
+		page, err := s.get(baseURL + link)
+		if err != nil {
+			continue
+		}
+
+		for _, m := range rowRegexp.FindAllStringSubmatch(page, -1) {
+			language := strings.TrimSpace(m[2])
+			if q.Language != "" && !strings.EqualFold(language, q.Language) {
+				continue
+			}
+
+			rating, _ := strconv.ParseFloat(m[1], 64)
+
+			subs = append(subs, source.Subtitle{
+				Title:      strings.TrimSpace(m[4]),
+				Language:   language,
+				Format:     "srt",
+				Rating:     rating,
+				SourceName: s.Name(),
+				Ref:        baseURL + m[3],
+			})
+
+			if q.Limit > 0 && len(subs) >= q.Limit {
+				return subs, nil
+			}
+		}
+	}
+
+	return subs, nil
+}
+
+// uniqueMatches returns the first submatch group of every match of re
+// against body, without duplicates, in order of first appearance.
+func uniqueMatches(re *regexp.Regexp, body string) []string {
+	seen := make(map[string]bool)
+	matches := make([]string, 0)
+
+	for _, m := range re.FindAllStringSubmatch(body, -1) {
+		if seen[m[1]] {
+			continue
+		}
+		seen[m[1]] = true
+		matches = append(matches, m[1])
+	}
+
+	return matches
+}
+
+// Download fetches the zip archive referenced by sub and returns a reader
+// over its first .srt entry.
+func (s Source) Download(sub source.Subtitle) (io.ReadCloser, error) {
+	zipURL, ok := sub.Ref.(string)
+	if !ok {
+		return nil, fmt.Errorf("yifysubtitles: subtitle %q wasn't returned by this source\n", sub.Title)
+	}
+
+	resp, err := s.HTTPClient.Get(zipURL)
+	if err != nil {
+		return nil, fmt.Errorf("yifysubtitles: couldn't fetch %s : %s\n", zipURL, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("yifysubtitles: couldn't open zip archive : %s\n", err)
+	}
+
+	for _, f := range zipReader.File {
+		if strings.HasSuffix(strings.ToLower(f.Name), ".srt") {
+			return f.Open()
+		}
+	}
+
+	return nil, fmt.Errorf("yifysubtitles: no .srt entry found in %s\n", zipURL)
+}
+
+// get issues a GET request and returns the response body as a string.
+func (s Source) get(url string) (string, error) {
+	resp, err := s.HTTPClient.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("yifysubtitles: couldn't fetch %s : %s\n", url, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return string(body), nil
+}